@@ -0,0 +1,69 @@
+package pair
+
+import "math"
+
+// aqiBreakpoint is one row of the US EPA PM2.5 concentration-to-AQI
+// piecewise-linear table.
+type aqiBreakpoint struct {
+	cLo, cHi float64
+	iLo, iHi float64
+	category string
+}
+
+// aqiBreakpoints is the US EPA PM2.5 breakpoint table, in ug/m3.
+var aqiBreakpoints = []aqiBreakpoint{
+	{0.0, 12.0, 0, 50, "Good"},
+	{12.1, 35.4, 51, 100, "Moderate"},
+	{35.5, 55.4, 101, 150, "Unhealthy for Sensitive Groups"},
+	{55.5, 150.4, 151, 200, "Unhealthy"},
+	{150.5, 250.4, 201, 300, "Very Unhealthy"},
+	{250.5, 500.4, 301, 500, "Hazardous"},
+}
+
+// AQIFromPM25 converts a PM2.5 concentration, in ug/m3, to an AQI
+// value using the US EPA breakpoint piecewise-linear formula.
+// Concentrations above the top of the table saturate at an AQI of
+// 500.
+func AQIFromPM25(ugm3 float64) int {
+	if ugm3 < 0 {
+		ugm3 = 0
+	}
+	bp := aqiBreakpoints[len(aqiBreakpoints)-1]
+	for _, b := range aqiBreakpoints {
+		if ugm3 <= b.cHi {
+			bp = b
+			break
+		}
+	}
+	if ugm3 > bp.cHi {
+		return 500
+	}
+	aqi := (bp.iHi-bp.iLo)/(bp.cHi-bp.cLo)*(ugm3-bp.cLo) + bp.iLo
+	return int(math.Round(aqi))
+}
+
+// AQICategory returns the EPA category name (e.g. "Good", "Moderate")
+// for an AQI value, per the same breakpoint table used by
+// AQIFromPM25.
+func AQICategory(aqi int) string {
+	for _, b := range aqiBreakpoints {
+		if float64(aqi) <= b.iHi {
+			return b.category
+		}
+	}
+	return aqiBreakpoints[len(aqiBreakpoints)-1].category
+}
+
+// CorrectedAQI applies the widely used PurpleAir PM2.5 correction
+// (PM2.5_corrected = 0.524*PM2.5_cf1 - 0.0862*humidity + 5.75,
+// clamped at 0) to the sensor's channel A CF=1 reading and humidity,
+// then converts the result to an AQI via AQIFromPM25.
+func (s *Sensor) CorrectedAQI() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	corrected := 0.524*s.LastSample.PM25Cf1 - 0.0862*float64(s.LastSample.CurrentHumidity) + 5.75
+	if corrected < 0 {
+		corrected = 0
+	}
+	return AQIFromPM25(corrected)
+}
@@ -0,0 +1,46 @@
+package pair
+
+import "testing"
+
+func TestAQIFromPM25(t *testing.T) {
+	for _, tc := range []struct {
+		ugm3 float64
+		want int
+	}{
+		{-5, 0},     // negative concentrations clamp to 0
+		{0, 0},      // bottom of the "Good" breakpoint
+		{12.0, 50},  // top of the "Good" breakpoint
+		{12.1, 51},  // bottom of "Moderate"
+		{35.4, 100}, // top of "Moderate"
+		{55.4, 150}, // top of "Unhealthy for Sensitive Groups"
+		{150.4, 200},
+		{250.4, 300},
+		{500.4, 500}, // top of the table
+		{1000, 500},  // saturates above the table
+	} {
+		if got := AQIFromPM25(tc.ugm3); got != tc.want {
+			t.Errorf("AQIFromPM25(%v) = %d, want %d", tc.ugm3, got, tc.want)
+		}
+	}
+}
+
+func TestAQICategory(t *testing.T) {
+	for _, tc := range []struct {
+		aqi  int
+		want string
+	}{
+		{0, "Good"},
+		{50, "Good"},
+		{51, "Moderate"},
+		{100, "Moderate"},
+		{101, "Unhealthy for Sensitive Groups"},
+		{151, "Unhealthy"},
+		{201, "Very Unhealthy"},
+		{301, "Hazardous"},
+		{600, "Hazardous"}, // above the table falls back to the last category
+	} {
+		if got := AQICategory(tc.aqi); got != tc.want {
+			t.Errorf("AQICategory(%d) = %q, want %q", tc.aqi, got, tc.want)
+		}
+	}
+}
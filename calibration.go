@@ -0,0 +1,93 @@
+package pair
+
+import "fmt"
+
+// CalibrationPoint is one paired (raw, reference) reading, collected
+// against an external, independently calibrated instrument.
+type CalibrationPoint struct {
+	Raw       float64
+	Reference float64
+}
+
+// FitTempPoly performs a least-squares fit of a degree-th order
+// polynomial to the given (raw, reference) calibration pairs, solving
+// the normal equations. The returned coefficients are ordered
+// constant-term first, matching the form expected by
+// Sensor.TempAdjust, Sensor.HumidityAdjust and Sensor.PressureAdjust.
+func FitTempPoly(samples []CalibrationPoint, degree int) ([]float64, error) {
+	if degree < 0 {
+		return nil, fmt.Errorf("degree must be non-negative, got %d", degree)
+	}
+	n := degree + 1
+	if len(samples) < n {
+		return nil, fmt.Errorf("need at least %d samples for a degree %d fit, got %d", n, degree, len(samples))
+	}
+
+	ata := make([][]float64, n)
+	for i := range ata {
+		ata[i] = make([]float64, n)
+	}
+	atb := make([]float64, n)
+
+	row := make([]float64, n)
+	for _, p := range samples {
+		x := 1.0
+		for i := 0; i < n; i++ {
+			row[i] = x
+			x *= p.Raw
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				ata[i][j] += row[i] * row[j]
+			}
+			atb[i] += row[i] * p.Reference
+		}
+	}
+
+	return solveLinear(ata, atb)
+}
+
+// solveLinear solves the n-by-n linear system a*x = b via Gaussian
+// elimination with partial pivoting, for the small, well-conditioned
+// systems FitTempPoly builds.
+func solveLinear(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if abs(a[row][col]) > abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if a[pivot][col] == 0 {
+			return nil, fmt.Errorf("singular system, cannot fit a polynomial to these samples")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, nil
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
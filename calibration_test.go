@@ -0,0 +1,75 @@
+package pair
+
+import "testing"
+
+func TestFitTempPolyExact(t *testing.T) {
+	// y = 2x + 3, sampled exactly: the fit should recover the
+	// coefficients with no residual.
+	samples := []CalibrationPoint{
+		{Raw: 0, Reference: 3},
+		{Raw: 1, Reference: 5},
+		{Raw: 2, Reference: 7},
+		{Raw: 3, Reference: 9},
+	}
+	coef, err := FitTempPoly(samples, 1)
+	if err != nil {
+		t.Fatalf("FitTempPoly: %v", err)
+	}
+	want := []float64{3, 2}
+	if len(coef) != len(want) {
+		t.Fatalf("FitTempPoly coef = %v, want %v", coef, want)
+	}
+	for i, c := range want {
+		if d := coef[i] - c; d < -1e-9 || d > 1e-9 {
+			t.Errorf("coef[%d] = %v, want %v", i, coef[i], c)
+		}
+	}
+}
+
+func TestFitTempPolyQuadraticExact(t *testing.T) {
+	// y = 1 - x + 2x^2, sampled exactly.
+	samples := []CalibrationPoint{
+		{Raw: -1, Reference: 4},
+		{Raw: 0, Reference: 1},
+		{Raw: 1, Reference: 2},
+		{Raw: 2, Reference: 7},
+	}
+	coef, err := FitTempPoly(samples, 2)
+	if err != nil {
+		t.Fatalf("FitTempPoly: %v", err)
+	}
+	want := []float64{1, -1, 2}
+	for i, c := range want {
+		if d := coef[i] - c; d < -1e-9 || d > 1e-9 {
+			t.Errorf("coef[%d] = %v, want %v", i, coef[i], c)
+		}
+	}
+}
+
+func TestFitTempPolyTooFewSamples(t *testing.T) {
+	samples := []CalibrationPoint{{Raw: 0, Reference: 1}}
+	if _, err := FitTempPoly(samples, 1); err == nil {
+		t.Fatal("FitTempPoly with 1 sample for a degree 1 fit, want error")
+	}
+}
+
+func TestFitTempPolyNegativeDegree(t *testing.T) {
+	samples := []CalibrationPoint{{Raw: 0, Reference: 1}}
+	if _, err := FitTempPoly(samples, -1); err == nil {
+		t.Fatal("FitTempPoly with negative degree, want error")
+	}
+}
+
+func TestFitTempPolySingular(t *testing.T) {
+	// Every sample has the same Raw value, so the normal equations
+	// are singular for a degree 1 fit: there's no information to
+	// distinguish the slope from the intercept.
+	samples := []CalibrationPoint{
+		{Raw: 5, Reference: 1},
+		{Raw: 5, Reference: 2},
+		{Raw: 5, Reference: 3},
+	}
+	if _, err := FitTempPoly(samples, 1); err == nil {
+		t.Fatal("FitTempPoly with degenerate samples, want error")
+	}
+}
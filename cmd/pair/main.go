@@ -0,0 +1,53 @@
+// Program pair is a small multi-command CLI for working with stored
+// PurpleAir history. Live sensor polling lives in examples/query.go;
+// this binary operates on a configured store.Store.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"zappem.net/pub/net/pair/store"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: pair query --store <url> [--since <duration>]")
+	}
+	switch cmd := os.Args[1]; cmd {
+	case "query":
+		runQuery(os.Args[2:])
+	default:
+		log.Fatalf("unknown subcommand %q, want: query", cmd)
+	}
+}
+
+func runQuery(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	storeURL := fs.String("store", "", "store URL to query: file://path, sqlite://path or postgres://dsn")
+	since := fs.Duration("since", 24*time.Hour, "how far back from now to query")
+	fs.Parse(args)
+
+	if *storeURL == "" {
+		log.Fatal("--store is required")
+	}
+	st, err := store.Open(*storeURL)
+	if err != nil {
+		log.Fatalf("failed to open --store %q: %v", *storeURL, err)
+	}
+	defer st.Close()
+
+	end := time.Now()
+	samples, err := st.Query(context.Background(), store.Range{Start: end.Add(-*since), End: end})
+	if err != nil {
+		log.Fatalf("query failed: %v", err)
+	}
+	for _, s := range samples {
+		fmt.Printf("%s\t%s\t%s\ttemp=%.1f hum=%.1f pres=%.1f pm25=%.1f,%.1f aqi=%.1f,%.1f\n",
+			s.Time.Format(time.RFC3339), s.SensorID, s.Place, s.Temp, s.Humidity, s.Pressure, s.PM25A, s.PM25B, s.AQIA, s.AQIB)
+	}
+}
@@ -0,0 +1,122 @@
+// Program pair_calibrate fits a calibration polynomial from a CSV of
+// paired (raw, reference) readings, e.g. collected against an
+// external thermometer, hygrometer or barometer, so users don't have
+// to hand-compute coefficients for Sensor.TempAdjust,
+// Sensor.HumidityAdjust or Sensor.PressureAdjust.
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+
+	"zappem.net/pub/net/pair"
+)
+
+var (
+	csvPath = flag.String("csv", "", "CSV file of raw,reference calibration pairs; non-numeric rows (e.g. a header) are skipped")
+	degree  = flag.Int("degree", 1, "degree of the fitted polynomial")
+)
+
+func main() {
+	flag.Parse()
+
+	if *csvPath == "" {
+		log.Fatal("--csv <path> is required")
+	}
+	samples, err := readSamples(*csvPath)
+	if err != nil {
+		log.Fatalf("failed to read %q: %v", *csvPath, err)
+	}
+	coef, err := pair.FitTempPoly(samples, *degree)
+	if err != nil {
+		log.Fatalf("fit failed: %v", err)
+	}
+	r2, meanResid, stddevResid := residualStats(samples, coef)
+	fmt.Printf("coefficients: %v\n", coef)
+	fmt.Printf("R^2: %.4f\n", r2)
+	fmt.Printf("residual mean: %.4f, stddev: %.4f\n", meanResid, stddevResid)
+}
+
+// readSamples parses raw,reference pairs from a CSV file, skipping
+// any row (such as a header) that doesn't parse as two floats.
+func readSamples(path string) ([]pair.CalibrationPoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var samples []pair.CalibrationPoint
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		raw, err1 := strconv.ParseFloat(row[0], 64)
+		ref, err2 := strconv.ParseFloat(row[1], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		samples = append(samples, pair.CalibrationPoint{Raw: raw, Reference: ref})
+	}
+	return samples, nil
+}
+
+// residualStats returns the coefficient of determination and the
+// mean and standard deviation of the fit's residuals.
+func residualStats(samples []pair.CalibrationPoint, coef []float64) (r2, mean, stddev float64) {
+	n := float64(len(samples))
+	var meanRef float64
+	for _, s := range samples {
+		meanRef += s.Reference
+	}
+	meanRef /= n
+
+	var ssTot, ssRes, sumResid float64
+	for _, s := range samples {
+		resid := s.Reference - evalPoly(coef, s.Raw)
+		ssRes += resid * resid
+		ssTot += (s.Reference - meanRef) * (s.Reference - meanRef)
+		sumResid += resid
+	}
+	if ssTot == 0 {
+		// Every reference reading is identical (e.g. a single-point
+		// calibration run): there's no variance for the fit to
+		// explain, so call it a perfect fit iff the residuals also
+		// vanish.
+		if ssRes == 0 {
+			r2 = 1
+		} else {
+			r2 = 0
+		}
+	} else {
+		r2 = 1 - ssRes/ssTot
+	}
+	mean = sumResid / n
+
+	var varResid float64
+	for _, s := range samples {
+		resid := s.Reference - evalPoly(coef, s.Raw)
+		d := resid - mean
+		varResid += d * d
+	}
+	stddev = math.Sqrt(varResid / n)
+	return r2, mean, stddev
+}
+
+func evalPoly(coef []float64, x float64) float64 {
+	v, p := 0.0, 1.0
+	for _, c := range coef {
+		v += c * p
+		p *= x
+	}
+	return v
+}
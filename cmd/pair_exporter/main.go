@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"zappem.net/pub/net/pair"
+	"zappem.net/pub/net/pair/exporter"
+)
+
+// Program pair_exporter serves Prometheus metrics for one or more
+// PurpleAir sensors, refreshing each sensor's readings on every
+// scrape of /metrics.
+
+var (
+	listen = flag.String("listen", ":9141", "address to serve /metrics on")
+	sensor = flag.String("sensor", "", "comma separated id=place=addr triples, e.g. back=yard=192.168.1.42")
+)
+
+func main() {
+	flag.Parse()
+
+	if *sensor == "" {
+		log.Fatal("--sensor id=place=addr[,id=place=addr...] is required")
+	}
+
+	c := exporter.NewCollector()
+	for _, triple := range strings.Split(*sensor, ",") {
+		parts := strings.SplitN(triple, "=", 3)
+		if len(parts) != 3 {
+			log.Fatalf("malformed --sensor entry %q, want id=place=addr", triple)
+		}
+		id, place, addr := parts[0], parts[1], parts[2]
+		c.Add(id, place, pair.NewSensor(addr))
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+
+	http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	log.Printf("serving /metrics on %s", *listen)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+
+	"zappem.net/pub/net/pair"
+	"zappem.net/pub/net/pair/mqtt"
+)
+
+// Program pair_mqtt polls a PurpleAir sensor and republishes its
+// readings to MQTT, with Home Assistant discovery, using the same
+// pair.Fleet retry/backoff policy as examples/query.go.
+
+var (
+	addr    = flag.String("sensor", "", "local network address of sensor")
+	id      = flag.String("id", "", "sensor id used in MQTT topics and Home Assistant, defaults to --sensor")
+	place   = flag.String("place", "", "human readable device name for Home Assistant")
+	broker  = flag.String("broker", "tcp://localhost:1883", "MQTT broker URL")
+	prefix  = flag.String("prefix", "pair", "state topic prefix")
+	poll    = flag.Duration("poll", 60*time.Second, "polling interval")
+	retry   = flag.Int("retry", 3, "default number of times to retry request - once a second, after which can exponentially backoff")
+	backoff = flag.Bool("backoff", true, "should --retry reads fail, backoff exponentially but don't give up")
+	timeout = flag.Duration("timeout", 10*time.Second, "per-request timeout for sensor refreshes, 0 disables")
+)
+
+func main() {
+	flag.Parse()
+
+	if *addr == "" {
+		log.Fatal("--sensor <net-address>, is required")
+	}
+	sensorID := *id
+	if sensorID == "" {
+		sensorID = *addr
+	}
+
+	opts := paho.NewClientOptions().AddBroker(*broker).SetClientID("pair_mqtt_" + sensorID)
+	client := paho.NewClient(opts)
+	if tok := client.Connect(); tok.Wait() && tok.Error() != nil {
+		log.Fatalf("failed to connect to %s: %v", *broker, tok.Error())
+	}
+	defer client.Disconnect(250)
+
+	s := pair.NewSensor(*addr)
+	b := mqtt.NewBridge(client, s, sensorID, mqtt.BridgeOptions{
+		Prefix:     *prefix,
+		DeviceName: *place,
+		Retain:     true,
+	})
+
+	f := pair.NewFleet()
+	f.Retries = *retry
+	f.Timeout = *timeout
+	f.Add(*addr, s)
+	for ev := range f.Run(context.Background(), *poll) {
+		switch ev.Kind {
+		case pair.FleetSuccess:
+			if err := b.Publish(); err != nil {
+				log.Printf("failed to publish to MQTT: %v", err)
+			}
+		case pair.FleetFailure:
+			log.Printf("refresh failed: %v", ev.Err)
+		case pair.FleetBackoff:
+			if err := b.Offline(); err != nil {
+				log.Printf("failed to publish offline status: %v", err)
+			}
+			if !*backoff {
+				log.Fatalf("failed to read sensor after --retry=%d attempts", *retry)
+			}
+			log.Printf("retrying with backoff (%v)", ev.Backoff)
+		}
+	}
+}
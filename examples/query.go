@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"strconv"
@@ -8,6 +9,8 @@ import (
 	"time"
 
 	"zappem.net/pub/net/pair"
+	"zappem.net/pub/net/pair/filter"
+	"zappem.net/pub/net/pair/store"
 )
 
 // Program query performs a local query to a PurpleAir sensor and
@@ -18,7 +21,19 @@ var (
 	poll    = flag.Duration("poll", 0*time.Second, "non-zero polls with this interval")
 	retry   = flag.Int("retry", 3, "default number of times to retry request - once a second, after which can exponentially backoff")
 	backoff = flag.Bool("backoff", true, "if --poll != 0, should a --retry reads fail, backoff exponentially but don't give up")
+	timeout = flag.Duration("timeout", 10*time.Second, "per-request timeout for sensor refreshes, 0 disables")
 	coef    = flag.String("coef", "-8.9037,1.0441", "comma separated coefficients for temperature conversion")
+
+	filterKind      = flag.String("filter", "none", "smoothing filter for temp and AQI: none, sma, ewma")
+	filterWindow    = flag.Int("filter-window", 5, "window size for --filter=sma")
+	filterAlpha     = flag.Float64("filter-alpha", 0.3, "smoothing factor for --filter=ewma")
+	filterWeightA   = flag.Float64("filter-weight-a", 1, "weight of the PM2.5 A channel when smoothing")
+	filterWeightB   = flag.Float64("filter-weight-b", 1, "weight of the PM2.5 B channel when smoothing")
+	filterThreshold = flag.Float64("filter-threshold", 0, "A/B AQI disagreement beyond which the outlier channel is down-weighted, 0 disables")
+
+	storeURL   = flag.String("store", "", "push readings to this store: file://path, sqlite://path or postgres://dsn")
+	storeID    = flag.String("store-id", "", "sensor_id label for stored samples, defaults to --sensor")
+	storePlace = flag.String("store-place", "", "place label for stored samples")
 )
 
 func main() {
@@ -39,41 +54,99 @@ func main() {
 		}
 		s.TempAdjust(cs)
 	}
-	retries := *retry
-	bo := time.Duration(0)
-	for {
-		if err := s.Refresh(); err != nil {
-			if retries > 0 {
-				retries--
-				time.Sleep(1 * time.Second)
-				continue
-			}
+	var kind filter.Kind
+	switch *filterKind {
+	case "none":
+		kind = filter.None
+	case "sma":
+		kind = filter.SimpleMovingAverage
+	case "ewma":
+		kind = filter.ExponentialMovingAverage
+	default:
+		log.Fatalf("unknown --filter %q, want none, sma or ewma", *filterKind)
+	}
+	s.SetFilter(filter.Config{
+		Kind:      kind,
+		Window:    *filterWindow,
+		Alpha:     *filterAlpha,
+		WeightA:   *filterWeightA,
+		WeightB:   *filterWeightB,
+		Threshold: *filterThreshold,
+	})
+	if *storeURL != "" {
+		st, err := store.Open(*storeURL)
+		if err != nil {
+			log.Fatalf("failed to open --store %q: %v", *storeURL, err)
+		}
+		defer st.Close()
+		id := *storeID
+		if id == "" {
+			id = *addr
+		}
+		s.SetStore(st, id, *storePlace)
+	}
+	if *poll == 0 {
+		if err := refreshWithRetry(s, *retry, *timeout); err != nil {
+			log.Fatalf("failed to read sensor after --retry=%d attempts: %v", *retry, err)
+		}
+		logReading(s)
+		return
+	}
+
+	// Continuous polling is delegated to a single-sensor pair.Fleet,
+	// which owns its own per-sensor exponential backoff.
+	f := pair.NewFleet()
+	f.Retries = *retry
+	f.Timeout = *timeout
+	f.Add(*addr, s)
+	for ev := range f.Run(context.Background(), *poll) {
+		switch ev.Kind {
+		case pair.FleetSuccess:
+			logReading(s)
+		case pair.FleetFailure:
+			log.Printf("refresh failed: %v", ev.Err)
+		case pair.FleetBackoff:
 			if !*backoff {
 				log.Fatalf("failed to read sensor after --retry=%d attempts", *retry)
 			}
-			if bo == 0 {
-				bo = time.Second
-			} else {
-				bo *= 2
-			}
-			log.Printf("retrying with backoff (%v)", bo)
-			time.Sleep(bo)
-			continue
+			log.Printf("retrying with backoff (%v)", ev.Backoff)
 		}
-		t := s.Temp()
-		tC := pair.FtoC(t)
-		dew := s.DewPoint()
-		dewC := pair.FtoC(dew)
-		pres := s.Pressure()
-		hum := s.Humidity()
-		aqiA := s.AQIA()
-		aqiB := s.AQIB()
-		log.Printf("temp=%.1fF(%.1fC) dewPt=%.1fF(%.1fC) hum=%g%% pres=%.1fhPa AQIab=%.1f,%.1f", t, tC, dew, dewC, hum, pres, aqiA, aqiB)
-		if *poll == 0 {
-			break
+	}
+}
+
+// refreshWithRetry refreshes s, bounding each attempt by timeout if it
+// is non-zero, retrying once a second up to retries times before
+// giving up.
+func refreshWithRetry(s *pair.Sensor, retries int, timeout time.Duration) error {
+	for {
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		err := s.RefreshContext(ctx)
+		cancel()
+		if err == nil || retries <= 0 {
+			return err
 		}
-		time.Sleep(*poll)
-		retries = *retry
-		bo = 0
+		retries--
+		time.Sleep(time.Second)
 	}
 }
+
+// logReading logs s's current raw, smoothed and EPA-corrected
+// readings.
+func logReading(s *pair.Sensor) {
+	t := s.Temp()
+	tC := pair.FtoC(t)
+	dew := s.DewPoint()
+	dewC := pair.FtoC(dew)
+	pres := s.Pressure()
+	hum := s.Humidity()
+	aqiA := s.AQIA()
+	aqiB := s.AQIB()
+	smoothT := s.SmoothedTemp()
+	smoothAQI := s.SmoothedAQI()
+	corrected := s.CorrectedAQI()
+	log.Printf("temp=%.1fF(%.1fC) dewPt=%.1fF(%.1fC) hum=%g%% pres=%.1fhPa AQIab=%.1f,%.1f smoothedTemp=%.1fF smoothedAQI=%.1f correctedAQI=%d(%s)",
+		t, tC, dew, dewC, hum, pres, aqiA, aqiB, smoothT, smoothAQI, corrected, pair.AQICategory(corrected))
+}
@@ -0,0 +1,198 @@
+// Package exporter adapts one or more *pair.Sensor instances into a
+// Prometheus Collector, so a fleet of local PurpleAir sensors can be
+// scraped directly without an intermediate time-series database.
+package exporter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"zappem.net/pub/net/pair"
+)
+
+// defaultTimeout bounds each sensor's refresh for a scrape, so one
+// unresponsive sensor can't hang the whole /metrics handler.
+const defaultTimeout = 10 * time.Second
+
+// entry pairs up a sensor with the labels used to identify it in
+// exported metrics.
+type entry struct {
+	sensor *pair.Sensor
+	id     string
+	place  string
+	addr   string
+
+	mu           sync.Mutex
+	lastRefresh  time.Time
+	lastDuration time.Duration
+	up           bool
+}
+
+// Collector implements prometheus.Collector over a fleet of
+// registered PurpleAir sensors. Each scrape triggers a fresh,
+// concurrent, timeout-bounded refresh of every sensor so the exported
+// values reflect the current state of the hardware and one
+// unresponsive sensor can't stall the others.
+type Collector struct {
+	mu      sync.Mutex
+	sensors []*entry
+
+	// Timeout bounds each sensor's refresh; the zero value from a
+	// zero-value Collector is replaced with defaultTimeout by
+	// NewCollector.
+	Timeout time.Duration
+
+	temperature   *prometheus.Desc
+	humidity      *prometheus.Desc
+	pressure      *prometheus.Desc
+	pm25Aqi       *prometheus.Desc
+	pm25Atm       *prometheus.Desc
+	particleCount *prometheus.Desc
+	up            *prometheus.Desc
+	lastRefresh   *prometheus.Desc
+	lastDuration  *prometheus.Desc
+	rssi          *prometheus.Desc
+	uptime        *prometheus.Desc
+}
+
+// NewCollector creates an empty Collector. Use Add to register
+// sensors before handing the Collector to a prometheus.Registry.
+func NewCollector() *Collector {
+	labels := []string{"sensor_id", "place", "addr"}
+	channelLabels := append(append([]string{}, labels...), "channel")
+	particleLabels := append(append([]string{}, channelLabels...), "size_um")
+	return &Collector{
+		Timeout:       defaultTimeout,
+		temperature:   prometheus.NewDesc("purpleair_temperature", "Calibrated sensor temperature.", labels, nil),
+		humidity:      prometheus.NewDesc("purpleair_humidity_percent", "Relative humidity percentage.", labels, nil),
+		pressure:      prometheus.NewDesc("purpleair_pressure_hpa", "Atmospheric pressure in hPa.", labels, nil),
+		pm25Aqi:       prometheus.NewDesc("purpleair_pm25_aqi", "PM2.5 AQI as reported by the sensor.", channelLabels, nil),
+		pm25Atm:       prometheus.NewDesc("purpleair_pm25_atm_ugm3", "PM2.5 atmospheric concentration in ug/m3.", channelLabels, nil),
+		particleCount: prometheus.NewDesc("purpleair_particle_count", "Particle count per deciliter for a given size bin.", particleLabels, nil),
+		up:            prometheus.NewDesc("purpleair_up", "1 if the last scrape successfully refreshed the sensor.", labels, nil),
+		lastRefresh:   prometheus.NewDesc("purpleair_last_refresh_time", "Unix timestamp of the last successful refresh.", labels, nil),
+		lastDuration:  prometheus.NewDesc("purpleair_last_refresh_duration_seconds", "Duration of the last refresh attempt.", labels, nil),
+		rssi:          prometheus.NewDesc("purpleair_rssi_dbm", "WiFi signal strength in dBm.", labels, nil),
+		uptime:        prometheus.NewDesc("purpleair_uptime_seconds", "Sensor uptime in seconds.", labels, nil),
+	}
+}
+
+// Add registers a sensor with the Collector. id and place are used as
+// metric labels alongside addr; id is typically the PurpleAir sensor
+// id or a user-chosen friendly name.
+func (c *Collector) Add(id, place string, s *pair.Sensor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sensors = append(c.sensors, &entry{
+		sensor: s,
+		id:     id,
+		place:  place,
+		addr:   s.Addr,
+	})
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.temperature
+	ch <- c.humidity
+	ch <- c.pressure
+	ch <- c.pm25Aqi
+	ch <- c.pm25Atm
+	ch <- c.particleCount
+	ch <- c.up
+	ch <- c.lastRefresh
+	ch <- c.lastDuration
+	ch <- c.rssi
+	ch <- c.uptime
+}
+
+// Collect implements prometheus.Collector, refreshing every
+// registered sensor, concurrently and with a bounded per-sensor
+// timeout, before exporting its current values. A sensor that times
+// out or errors only drops its own metrics for this scrape; it
+// doesn't block the others.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	es := append([]*entry{}, c.sensors...)
+	timeout := c.Timeout
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, e := range es {
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			e.refresh(timeout)
+		}(e)
+	}
+	wg.Wait()
+
+	for _, e := range es {
+		e.collect(c, ch)
+	}
+}
+
+// refresh polls the sensor, bounding the attempt by timeout, and
+// records the outcome for the up, last_refresh_time and
+// last_refresh_duration_seconds metrics.
+func (e *entry) refresh(timeout time.Duration) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := e.sensor.RefreshContext(ctx)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastDuration = time.Since(start)
+	e.up = err == nil
+	if err == nil {
+		e.lastRefresh = start
+	}
+}
+
+func (e *entry) collect(c *Collector, ch chan<- prometheus.Metric) {
+	labels := []string{e.id, e.place, e.addr}
+
+	e.mu.Lock()
+	up, lastRefresh, lastDuration := e.up, e.lastRefresh, e.lastDuration
+	e.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, boolToFloat(up), labels...)
+	ch <- prometheus.MustNewConstMetric(c.lastDuration, prometheus.GaugeValue, lastDuration.Seconds(), labels...)
+	if !up {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.lastRefresh, prometheus.GaugeValue, float64(lastRefresh.Unix()), labels...)
+	ch <- prometheus.MustNewConstMetric(c.temperature, prometheus.GaugeValue, e.sensor.Temp(), labels...)
+	ch <- prometheus.MustNewConstMetric(c.humidity, prometheus.GaugeValue, e.sensor.Humidity(), labels...)
+	ch <- prometheus.MustNewConstMetric(c.pressure, prometheus.GaugeValue, e.sensor.Pressure(), labels...)
+	ch <- prometheus.MustNewConstMetric(c.rssi, prometheus.GaugeValue, float64(e.sensor.RSSI()), labels...)
+	ch <- prometheus.MustNewConstMetric(c.uptime, prometheus.GaugeValue, float64(e.sensor.Uptime()), labels...)
+
+	for _, ch2 := range []struct {
+		channel string
+		aqi     float64
+		atm     float64
+		counts  map[string]float64
+	}{
+		{"a", e.sensor.AQIA(), e.sensor.PM25AtmA(), e.sensor.ParticleCountsA()},
+		{"b", e.sensor.AQIB(), e.sensor.PM25AtmB(), e.sensor.ParticleCountsB()},
+	} {
+		cl := append(append([]string{}, labels...), ch2.channel)
+		ch <- prometheus.MustNewConstMetric(c.pm25Aqi, prometheus.GaugeValue, ch2.aqi, cl...)
+		ch <- prometheus.MustNewConstMetric(c.pm25Atm, prometheus.GaugeValue, ch2.atm, cl...)
+		for size, count := range ch2.counts {
+			pl := append(append([]string{}, cl...), size)
+			ch <- prometheus.MustNewConstMetric(c.particleCount, prometheus.GaugeValue, count, pl...)
+		}
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
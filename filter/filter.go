@@ -0,0 +1,192 @@
+// Package filter provides rolling smoothers for noisy PurpleAir
+// readings, such as a simple moving average, an exponentially
+// weighted moving average, and a combiner that blends the sensor's
+// redundant A and B PM2.5 channels.
+package filter
+
+import "math"
+
+// Smoother incrementally folds a stream of samples into a single
+// smoothed value.
+type Smoother interface {
+	// Add feeds in a new sample and returns the updated smoothed
+	// value.
+	Add(v float64) float64
+
+	// Value returns the current smoothed value without adding a
+	// sample.
+	Value() float64
+}
+
+// MovingAverage is a fixed-size sliding window simple moving average.
+type MovingAverage struct {
+	window []float64
+	next   int
+	filled bool
+}
+
+// NewMovingAverage returns a MovingAverage over the last size
+// samples. A size less than 1 is treated as 1, i.e. no smoothing.
+func NewMovingAverage(size int) *MovingAverage {
+	if size < 1 {
+		size = 1
+	}
+	return &MovingAverage{window: make([]float64, size)}
+}
+
+// Add implements Smoother.
+func (m *MovingAverage) Add(v float64) float64 {
+	m.window[m.next] = v
+	m.next = (m.next + 1) % len(m.window)
+	if m.next == 0 {
+		m.filled = true
+	}
+	return m.Value()
+}
+
+// Value implements Smoother.
+func (m *MovingAverage) Value() float64 {
+	n := len(m.window)
+	if !m.filled {
+		n = m.next
+	}
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += m.window[i]
+	}
+	return sum / float64(n)
+}
+
+// EWMA is an exponentially weighted moving average with smoothing
+// factor Alpha in (0, 1]; larger values track new samples faster.
+type EWMA struct {
+	Alpha float64
+
+	value       float64
+	initialized bool
+}
+
+// NewEWMA returns an EWMA with the given smoothing factor.
+func NewEWMA(alpha float64) *EWMA {
+	return &EWMA{Alpha: alpha}
+}
+
+// Add implements Smoother.
+func (e *EWMA) Add(v float64) float64 {
+	if !e.initialized {
+		e.value = v
+		e.initialized = true
+	} else {
+		e.value = e.Alpha*v + (1-e.Alpha)*e.value
+	}
+	return e.value
+}
+
+// Value implements Smoother.
+func (e *EWMA) Value() float64 {
+	return e.value
+}
+
+// Kind selects which Smoother implementation a Config builds.
+type Kind int
+
+const (
+	// None applies no smoothing; each sample passes straight through.
+	None Kind = iota
+	// SimpleMovingAverage smooths over a fixed window of samples.
+	SimpleMovingAverage
+	// ExponentialMovingAverage smooths with a decaying weight on past
+	// samples.
+	ExponentialMovingAverage
+)
+
+// Config selects and parameterizes the smoothing behavior applied to
+// a Sensor via Sensor.SetFilter.
+type Config struct {
+	Kind Kind
+
+	// Window is the sample count used by SimpleMovingAverage.
+	Window int
+
+	// Alpha is the smoothing factor used by ExponentialMovingAverage.
+	Alpha float64
+
+	// WeightA and WeightB weight the PM2.5 A and B channels when
+	// combined by NewCombiner. The zero value for both weights means
+	// equal weighting.
+	WeightA, WeightB float64
+
+	// Threshold is the absolute A/B AQI disagreement beyond which
+	// Combiner.Add down-weights whichever channel has drifted
+	// furthest from its own smoothed history, a common PurpleAir
+	// failure mode. Zero disables down-weighting.
+	Threshold float64
+}
+
+// New returns a fresh Smoother configured per c.Kind.
+func (c Config) New() Smoother {
+	switch c.Kind {
+	case SimpleMovingAverage:
+		return NewMovingAverage(c.Window)
+	case ExponentialMovingAverage:
+		return NewEWMA(c.Alpha)
+	default:
+		return NewMovingAverage(1)
+	}
+}
+
+// NewCombiner returns a fresh Combiner for a pair of redundant
+// channels (e.g. PurpleAir's PM2.5 A and B readings), each smoothed
+// per c and blended per c.WeightA, c.WeightB and c.Threshold.
+func (c Config) NewCombiner() *Combiner {
+	wa, wb := c.WeightA, c.WeightB
+	if wa == 0 && wb == 0 {
+		wa, wb = 1, 1
+	}
+	return &Combiner{
+		A:         c.New(),
+		B:         c.New(),
+		WeightA:   wa,
+		WeightB:   wb,
+		Threshold: c.Threshold,
+	}
+}
+
+// Combiner smooths and blends two redundant channels into one value,
+// down-weighting whichever channel has drifted furthest from its own
+// smoothed history once the raw A/B disagreement exceeds Threshold.
+type Combiner struct {
+	A, B             Smoother
+	WeightA, WeightB float64
+	Threshold        float64
+
+	value float64
+}
+
+// Add feeds the raw A and B samples through their respective
+// smoothers and returns the combined value.
+func (c *Combiner) Add(a, b float64) float64 {
+	prevA, prevB := c.A.Value(), c.B.Value()
+	sa, sb := c.A.Add(a), c.B.Add(b)
+
+	wa, wb := c.WeightA, c.WeightB
+	if c.Threshold > 0 && math.Abs(a-b) > c.Threshold {
+		// Trust whichever channel moved least from its own smoothed
+		// history; down-weight the one that jumped.
+		if math.Abs(sa-prevA) > math.Abs(sb-prevB) {
+			wa *= 0.1
+		} else {
+			wb *= 0.1
+		}
+	}
+	c.value = (wa*sa + wb*sb) / (wa + wb)
+	return c.value
+}
+
+// Value returns the current combined value without adding a sample.
+func (c *Combiner) Value() float64 {
+	return c.value
+}
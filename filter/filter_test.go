@@ -0,0 +1,85 @@
+package filter
+
+import "testing"
+
+func TestMovingAverage(t *testing.T) {
+	m := NewMovingAverage(3)
+	want := []float64{1, 1.5, 2, 3, 4}
+	got := []float64{
+		m.Add(1),
+		m.Add(2),
+		m.Add(3),
+		m.Add(4), // window now holds {2,3,4}
+		m.Add(5), // window now holds {3,4,5}
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Add #%d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if got, want := m.Value(), 4.0; got != want {
+		t.Errorf("Value() = %v, want %v", got, want)
+	}
+}
+
+func TestMovingAverageMinSize(t *testing.T) {
+	m := NewMovingAverage(0)
+	m.Add(5)
+	if got, want := m.Add(7), 7.0; got != want {
+		t.Errorf("NewMovingAverage(0) should behave as size 1, Add(7) = %v, want %v", got, want)
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	e := NewEWMA(0.5)
+	if got, want := e.Add(10), 10.0; got != want {
+		t.Errorf("first Add = %v, want %v (seeds the average)", got, want)
+	}
+	if got, want := e.Add(20), 15.0; got != want {
+		t.Errorf("second Add = %v, want %v", got, want)
+	}
+	if got, want := e.Value(), 15.0; got != want {
+		t.Errorf("Value() = %v, want %v", got, want)
+	}
+}
+
+func TestCombinerNoThreshold(t *testing.T) {
+	c := Config{Kind: None}.NewCombiner()
+	if got, want := c.Add(10, 20), 15.0; got != want {
+		t.Errorf("Add(10, 20) = %v, want %v (equal weighting, no threshold)", got, want)
+	}
+}
+
+func TestCombinerWeighted(t *testing.T) {
+	c := Config{Kind: None, WeightA: 3, WeightB: 1}.NewCombiner()
+	if got, want := c.Add(10, 20), 12.5; got != want {
+		t.Errorf("Add(10, 20) = %v, want %v", got, want)
+	}
+}
+
+func TestCombinerThresholdDownWeightsOutlier(t *testing.T) {
+	// Establish a stable smoothed history for both channels, then
+	// have A jump far from it while B stays put: A should be
+	// down-weighted 10x in the blend.
+	c := Config{Kind: None, Threshold: 5}.NewCombiner()
+	for i := 0; i < 5; i++ {
+		c.Add(10, 10)
+	}
+	got := c.Add(100, 10)
+	want := (0.1*100 + 1*10) / (0.1 + 1)
+	if d := got - want; d < -1e-9 || d > 1e-9 {
+		t.Errorf("Add(100, 10) = %v, want %v (A down-weighted as the outlier)", got, want)
+	}
+}
+
+func TestCombinerThresholdBelowDoesNotDownWeight(t *testing.T) {
+	c := Config{Kind: None, Threshold: 50}.NewCombiner()
+	for i := 0; i < 5; i++ {
+		c.Add(10, 10)
+	}
+	got := c.Add(20, 10)
+	want := (20.0 + 10.0) / 2
+	if got != want {
+		t.Errorf("Add(20, 10) = %v, want %v (disagreement within threshold)", got, want)
+	}
+}
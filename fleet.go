@@ -0,0 +1,167 @@
+package pair
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FleetEventKind classifies a FleetEvent.
+type FleetEventKind int
+
+const (
+	// FleetSuccess reports a successful refresh.
+	FleetSuccess FleetEventKind = iota
+	// FleetFailure reports a failed refresh attempt.
+	FleetFailure
+	// FleetBackoff reports that a sensor is pausing before its next
+	// attempt, after one or more failures.
+	FleetBackoff
+)
+
+// FleetEvent is emitted on a Fleet's event channel as each of its
+// sensors is polled.
+type FleetEvent struct {
+	Name    string
+	Kind    FleetEventKind
+	Time    time.Time
+	Err     error
+	Backoff time.Duration
+}
+
+// Fleet manages concurrent polling of a group of named Sensors, with
+// per-sensor exponential backoff so one dead sensor doesn't block the
+// others.
+type Fleet struct {
+	mu      sync.Mutex
+	sensors map[string]*Sensor
+
+	// Retries is the number of times a failed refresh is retried, once
+	// a second, before Run engages exponential backoff for that
+	// sensor. The zero value backs off immediately on the first
+	// failure.
+	Retries int
+
+	// Timeout bounds each individual refresh attempt; the zero value
+	// leaves the attempt to run until ctx is done.
+	Timeout time.Duration
+}
+
+// NewFleet returns an empty Fleet. Use Add to register sensors before
+// calling Run.
+func NewFleet() *Fleet {
+	return &Fleet{sensors: make(map[string]*Sensor)}
+}
+
+// Add registers a sensor under name, replacing any sensor previously
+// registered under that name.
+func (f *Fleet) Add(name string, s *Sensor) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sensors[name] = s
+}
+
+// Run polls every registered sensor concurrently until ctx is done,
+// refreshing each one every interval. Events are emitted on the
+// returned channel as each sensor is polled; the channel is closed
+// once ctx is done and every sensor's goroutine has exited.
+func (f *Fleet) Run(ctx context.Context, interval time.Duration) <-chan FleetEvent {
+	f.mu.Lock()
+	sensors := make(map[string]*Sensor, len(f.sensors))
+	for name, s := range f.sensors {
+		sensors[name] = s
+	}
+	f.mu.Unlock()
+
+	ch := make(chan FleetEvent)
+	var wg sync.WaitGroup
+	for name, s := range sensors {
+		wg.Add(1)
+		go func(name string, s *Sensor) {
+			defer wg.Done()
+			pollFleetSensor(ctx, name, s, interval, f.Retries, f.Timeout, ch)
+		}(name, s)
+	}
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+	return ch
+}
+
+// pollFleetSensor refreshes s on interval until ctx is done, emitting
+// events on ch. A failed refresh is retried once a second, up to
+// retries times, before pollFleetSensor engages exponential backoff
+// for that sensor.
+func pollFleetSensor(ctx context.Context, name string, s *Sensor, interval time.Duration, retries int, timeout time.Duration, ch chan<- FleetEvent) {
+	bo := time.Duration(0)
+	for {
+		err := refreshOnce(ctx, s, timeout)
+		now := time.Now()
+		for attempt := 0; err != nil && attempt < retries; attempt++ {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case ch <- FleetEvent{Name: name, Kind: FleetFailure, Time: now, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			err = refreshOnce(ctx, s, timeout)
+			now = time.Now()
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if bo == 0 {
+				bo = time.Second
+			} else {
+				bo *= 2
+			}
+			select {
+			case ch <- FleetEvent{Name: name, Kind: FleetFailure, Time: now, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case ch <- FleetEvent{Name: name, Kind: FleetBackoff, Time: now, Backoff: bo}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(bo):
+			}
+			continue
+		}
+		bo = 0
+		select {
+		case ch <- FleetEvent{Name: name, Kind: FleetSuccess, Time: now}:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// refreshOnce refreshes s, bounding the attempt by timeout if it is
+// non-zero.
+func refreshOnce(ctx context.Context, s *Sensor, timeout time.Duration) error {
+	if timeout <= 0 {
+		return s.RefreshContext(ctx)
+	}
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return s.RefreshContext(cctx)
+}
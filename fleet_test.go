@@ -0,0 +1,148 @@
+package pair
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFailingSensor returns a *Sensor pointed at a local httptest
+// server whose /json?live=true handler fails (a non-JSON body) for
+// the first failures requests, then always succeeds.
+func newFailingSensor(t *testing.T, failures int32) *Sensor {
+	t.Helper()
+	var count int32
+	return newPatternedSensor(t, func() bool {
+		return atomic.AddInt32(&count, 1) <= failures
+	})
+}
+
+// newPatternedSensor returns a *Sensor pointed at a local httptest
+// server whose /json?live=true handler fails whenever shouldFail
+// returns true for that request.
+func newPatternedSensor(t *testing.T, shouldFail func() bool) *Sensor {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if shouldFail() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+	return NewSensor(srv.Listener.Addr().String())
+}
+
+// TestFleetRetryAbsorbsTransientFailure checks that a failure within
+// the Retries grace period is recovered without ever engaging
+// exponential backoff.
+func TestFleetRetryAbsorbsTransientFailure(t *testing.T) {
+	s := newFailingSensor(t, 1)
+
+	f := NewFleet()
+	f.Retries = 2
+	f.Add("s", s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var sawFailure, sawBackoff, sawSuccess bool
+	for ev := range f.Run(ctx, time.Hour) {
+		switch ev.Kind {
+		case FleetFailure:
+			sawFailure = true
+		case FleetBackoff:
+			sawBackoff = true
+		case FleetSuccess:
+			sawSuccess = true
+		}
+		if sawSuccess {
+			cancel()
+		}
+	}
+	if !sawFailure {
+		t.Error("expected a FleetFailure event for the transient failure")
+	}
+	if sawBackoff {
+		t.Error("retry grace period should have absorbed the failure before backoff engaged")
+	}
+	if !sawSuccess {
+		t.Error("expected a FleetSuccess event once the retry recovered")
+	}
+}
+
+// TestFleetBackoffDoublesAfterRetriesExhausted checks that once
+// Retries is exhausted, Run engages exponential backoff, doubling the
+// wait on each further failure.
+func TestFleetBackoffDoublesAfterRetriesExhausted(t *testing.T) {
+	s := newFailingSensor(t, 1000) // never recovers within the test
+
+	f := NewFleet()
+	f.Retries = 0 // no grace period: fail straight to backoff
+	f.Add("s", s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var backoffs []time.Duration
+	for ev := range f.Run(ctx, time.Hour) {
+		if ev.Kind == FleetBackoff {
+			backoffs = append(backoffs, ev.Backoff)
+			if len(backoffs) == 2 {
+				cancel()
+			}
+		}
+	}
+	if len(backoffs) < 2 {
+		t.Fatalf("got %d backoff events, want at least 2", len(backoffs))
+	}
+	if backoffs[0] != time.Second {
+		t.Errorf("first backoff = %v, want %v", backoffs[0], time.Second)
+	}
+	if backoffs[1] != 2*time.Second {
+		t.Errorf("second backoff = %v, want %v (should double)", backoffs[1], 2*time.Second)
+	}
+}
+
+// TestFleetSuccessResetsBackoff checks that a successful refresh
+// resets a sensor's backoff state, so a later run of failures starts
+// over at the base 1s backoff rather than continuing to double.
+func TestFleetSuccessResetsBackoff(t *testing.T) {
+	var count int32
+	s := newPatternedSensor(t, func() bool {
+		n := atomic.AddInt32(&count, 1)
+		// Fail once, succeed once, then fail forever: the second
+		// failure run should restart backoff at 1s rather than
+		// continuing to double from the first run.
+		return n != 2
+	})
+
+	f := NewFleet()
+	f.Retries = 0
+	f.Add("s", s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var backoffs []time.Duration
+	for ev := range f.Run(ctx, time.Millisecond) {
+		if ev.Kind == FleetBackoff {
+			backoffs = append(backoffs, ev.Backoff)
+			if len(backoffs) == 2 {
+				cancel()
+			}
+		}
+	}
+	if len(backoffs) < 2 {
+		t.Fatalf("got %d backoff events, want at least 2", len(backoffs))
+	}
+	if backoffs[0] != time.Second {
+		t.Errorf("backoff before the success = %v, want %v", backoffs[0], time.Second)
+	}
+	if backoffs[1] != time.Second {
+		t.Errorf("backoff after the success = %v, want %v (should reset, not double)", backoffs[1], time.Second)
+	}
+}
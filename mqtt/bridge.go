@@ -0,0 +1,158 @@
+// Package mqtt bridges a *pair.Sensor's live readings to MQTT,
+// publishing Home Assistant MQTT-discovery config messages so the
+// sensor auto-appears as a set of temperature, humidity, pressure,
+// PM2.5 and AQI entities.
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+
+	"zappem.net/pub/net/pair"
+)
+
+// BridgeOptions configures topic naming and publish behavior for a
+// Bridge.
+type BridgeOptions struct {
+	// Prefix is the state topic prefix; state topics are published as
+	// <Prefix>/<sensor id>/<field>. Defaults to "pair".
+	Prefix string
+
+	// DeviceName is the human-readable name used in Home Assistant,
+	// defaulting to the sensor id.
+	DeviceName string
+
+	// QoS is the MQTT quality of service used for all publishes.
+	QoS byte
+
+	// Retain controls whether state messages are retained by the
+	// broker. Discovery config messages are always retained, per the
+	// Home Assistant convention, and availability messages are always
+	// retained too.
+	Retain bool
+}
+
+// field describes one exported sensor measurement.
+type field struct {
+	key         string
+	name        string
+	unit        string
+	deviceClass string
+	value       func(s *pair.Sensor) float64
+}
+
+var fields = []field{
+	{"temperature", "Temperature", "°F", "temperature", func(s *pair.Sensor) float64 { return s.Temp() }},
+	{"humidity", "Humidity", "%", "humidity", func(s *pair.Sensor) float64 { return s.Humidity() }},
+	{"pressure", "Pressure", "hPa", "pressure", func(s *pair.Sensor) float64 { return s.Pressure() }},
+	{"pm25_a", "PM2.5 A", "µg/m³", "pm25", func(s *pair.Sensor) float64 { return s.PM25AtmA() }},
+	{"pm25_b", "PM2.5 B", "µg/m³", "pm25", func(s *pair.Sensor) float64 { return s.PM25AtmB() }},
+	{"pm25_aqi_a", "PM2.5 AQI A", "AQI", "aqi", func(s *pair.Sensor) float64 { return s.AQIA() }},
+	{"pm25_aqi_b", "PM2.5 AQI B", "AQI", "aqi", func(s *pair.Sensor) float64 { return s.AQIB() }},
+}
+
+// discoveryConfig is the Home Assistant MQTT-discovery payload for a
+// single sensor entity.
+type discoveryConfig struct {
+	Name                string `json:"name"`
+	UniqueID            string `json:"unique_id"`
+	StateTopic          string `json:"state_topic"`
+	UnitOfMeasurement   string `json:"unit_of_measurement,omitempty"`
+	DeviceClass         string `json:"device_class,omitempty"`
+	AvailabilityTopic   string `json:"availability_topic"`
+	PayloadAvailable    string `json:"payload_available"`
+	PayloadNotAvailable string `json:"payload_not_available"`
+}
+
+// Bridge republishes a Sensor's readings to MQTT and, on its first
+// Publish, sends retained Home Assistant discovery config for each
+// field.
+type Bridge struct {
+	client paho.Client
+	sensor *pair.Sensor
+	id     string
+	opts   BridgeOptions
+
+	configSent bool
+}
+
+// NewBridge returns a Bridge that publishes sensor's readings under
+// id, using client to talk to the broker.
+func NewBridge(client paho.Client, sensor *pair.Sensor, id string, opts BridgeOptions) *Bridge {
+	if opts.Prefix == "" {
+		opts.Prefix = "pair"
+	}
+	if opts.DeviceName == "" {
+		opts.DeviceName = id
+	}
+	return &Bridge{client: client, sensor: sensor, id: id, opts: opts}
+}
+
+// availabilityTopic returns the retained online/offline topic for
+// this bridge's sensor.
+func (b *Bridge) availabilityTopic() string {
+	return fmt.Sprintf("%s/%s/status", b.opts.Prefix, b.id)
+}
+
+func (b *Bridge) stateTopic(f field) string {
+	return fmt.Sprintf("%s/%s/%s", b.opts.Prefix, b.id, f.key)
+}
+
+// publishDiscovery sends a retained Home Assistant discovery config
+// message for every field.
+func (b *Bridge) publishDiscovery() error {
+	for _, f := range fields {
+		cfg := discoveryConfig{
+			Name:                fmt.Sprintf("%s %s", b.opts.DeviceName, f.name),
+			UniqueID:            fmt.Sprintf("%s_%s", b.id, f.key),
+			StateTopic:          b.stateTopic(f),
+			UnitOfMeasurement:   f.unit,
+			DeviceClass:         f.deviceClass,
+			AvailabilityTopic:   b.availabilityTopic(),
+			PayloadAvailable:    "online",
+			PayloadNotAvailable: "offline",
+		}
+		payload, err := json.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+		topic := fmt.Sprintf("homeassistant/sensor/%s/%s/config", b.id, f.key)
+		if tok := b.client.Publish(topic, b.opts.QoS, true, payload); tok.Wait() && tok.Error() != nil {
+			return tok.Error()
+		}
+	}
+	return nil
+}
+
+// Publish republishes the sensor's current readings to their state
+// topics. It first sends the Home Assistant discovery config, if that
+// has not yet been sent successfully, and then a retained "online"
+// availability message on every call.
+func (b *Bridge) Publish() error {
+	if !b.configSent {
+		if err := b.publishDiscovery(); err != nil {
+			return err
+		}
+		b.configSent = true
+	}
+	if tok := b.client.Publish(b.availabilityTopic(), b.opts.QoS, true, "online"); tok.Wait() && tok.Error() != nil {
+		return tok.Error()
+	}
+	for _, f := range fields {
+		payload := fmt.Sprintf("%v", f.value(b.sensor))
+		if tok := b.client.Publish(b.stateTopic(f), b.opts.QoS, b.opts.Retain, payload); tok.Wait() && tok.Error() != nil {
+			return tok.Error()
+		}
+	}
+	return nil
+}
+
+// Offline publishes a retained "offline" availability message, e.g.
+// when a refresh has failed repeatedly.
+func (b *Bridge) Offline() error {
+	tok := b.client.Publish(b.availabilityTopic(), b.opts.QoS, true, "offline")
+	tok.Wait()
+	return tok.Error()
+}
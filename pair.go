@@ -3,10 +3,15 @@
 package pair
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
+
+	"zappem.net/pub/net/pair/filter"
+	"zappem.net/pub/net/pair/store"
 )
 
 // Status is the structure returned by the PurpleAir sensors, via the
@@ -87,6 +92,19 @@ type Sensor struct {
 
 	// Polynomial best fit for temperature conversion.
 	TempPoly []float64
+
+	// Polynomial best fit for humidity conversion.
+	HumidityPoly []float64
+
+	// Polynomial best fit for pressure conversion.
+	PressurePoly []float64
+
+	tempSmoother filter.Smoother
+	aqiCombiner  *filter.Combiner
+
+	store      store.Store
+	storeID    string
+	storePlace string
 }
 
 // NewSensor registers a new sensor reference. This cannot fail, it
@@ -114,6 +132,50 @@ func (s *Sensor) TempAdjust(coef []float64) {
 	s.TempPoly = coef
 }
 
+// HumidityAdjust sets the polynomial expansion parameters used to
+// convert raw humidity readings to calibrated values. See TempAdjust
+// for details; the default is to not adjust the values, i.e. 1:1. Use
+// FitTempPoly against (raw, reference) pairs from a calibrated
+// hygrometer to compute these coefficients.
+func (s *Sensor) HumidityAdjust(coef []float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HumidityPoly = coef
+}
+
+// PressureAdjust sets the polynomial expansion parameters used to
+// convert raw pressure readings to calibrated values. See TempAdjust
+// for details; the default is to not adjust the values, i.e. 1:1. Use
+// FitTempPoly against (raw, reference) pairs from a calibrated
+// barometer to compute these coefficients.
+func (s *Sensor) PressureAdjust(coef []float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.PressurePoly = coef
+}
+
+// SetFilter configures the smoothers applied to this Sensor's
+// temperature and PM2.5 A/B readings. Each call replaces any
+// previously configured smoothers with fresh ones, discarding their
+// history. Use the zero filter.Config to disable smoothing.
+func (s *Sensor) SetFilter(cfg filter.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tempSmoother = cfg.New()
+	s.aqiCombiner = cfg.NewCombiner()
+}
+
+// SetStore configures Refresh to push a normalized store.Sample to st
+// after each successful poll, labeled with id and place. Pass a nil
+// st to stop pushing samples.
+func (s *Sensor) SetStore(st store.Store, id, place string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = st
+	s.storeID = id
+	s.storePlace = place
+}
+
 // expand a temperature measurement with the TempPoly coefficients.
 func actualTemp(coef []float64, raw float64) float64 {
 	if len(coef) == 0 {
@@ -162,14 +224,14 @@ func (s *Sensor) DewPoint() float64 {
 func (s *Sensor) Humidity() float64 {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return float64(s.LastSample.CurrentHumidity)
+	return actualTemp(s.HumidityPoly, float64(s.LastSample.CurrentHumidity))
 }
 
 // Pressure returns the current Pressure in hPa units.
 func (s *Sensor) Pressure() float64 {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.LastSample.Pressure
+	return actualTemp(s.PressurePoly, s.LastSample.Pressure)
 }
 
 // AQIA returns the AQI (Air Quality Index) value for sensor A.
@@ -186,9 +248,104 @@ func (s *Sensor) AQIB() float64 {
 	return float64(s.LastSample.PM25AqiB)
 }
 
-// Refresh fetches and updates the cached Sensor state.
+// SmoothedTemp returns the temperature smoothed per the filter.Config
+// supplied to SetFilter. If no filter has been configured, it returns
+// the same value as Temp.
+func (s *Sensor) SmoothedTemp() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tempSmoother == nil {
+		return actualTemp(s.TempPoly, float64(s.LastSample.CurrentTempF))
+	}
+	return s.tempSmoother.Value()
+}
+
+// SmoothedAQI returns the PM2.5 AQI A/B channels blended and smoothed
+// per the filter.Config supplied to SetFilter. If no filter has been
+// configured, it returns the unweighted average of AQIA and AQIB.
+func (s *Sensor) SmoothedAQI() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.aqiCombiner == nil {
+		return (float64(s.LastSample.PM25Aqi) + float64(s.LastSample.PM25AqiB)) / 2
+	}
+	return s.aqiCombiner.Value()
+}
+
+// PM25AtmA returns the PM2.5 atmospheric concentration, in ug/m3, as
+// measured by channel A.
+func (s *Sensor) PM25AtmA() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.LastSample.PM25Atm
+}
+
+// PM25AtmB returns the PM2.5 atmospheric concentration, in ug/m3, as
+// measured by channel B.
+func (s *Sensor) PM25AtmB() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.LastSample.PM25AtmB
+}
+
+// ParticleCountsA returns the per-bin particle counts reported by
+// channel A, keyed by the bin's nominal particle size in microns.
+func (s *Sensor) ParticleCountsA() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]float64{
+		"0.3":  s.LastSample.P03Um,
+		"0.5":  s.LastSample.P05Um,
+		"1.0":  s.LastSample.P10Um,
+		"2.5":  s.LastSample.P25Um,
+		"5.0":  s.LastSample.P50Um,
+		"10.0": s.LastSample.P100Um,
+	}
+}
+
+// ParticleCountsB returns the per-bin particle counts reported by
+// channel B, keyed by the bin's nominal particle size in microns.
+func (s *Sensor) ParticleCountsB() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]float64{
+		"0.3":  s.LastSample.PM03UmB,
+		"0.5":  s.LastSample.P05UmB,
+		"1.0":  s.LastSample.P10UmB,
+		"2.5":  s.LastSample.P25UmB,
+		"5.0":  s.LastSample.P50UmB,
+		"10.0": s.LastSample.P100UmB,
+	}
+}
+
+// RSSI returns the sensor's WiFi signal strength, in dBm.
+func (s *Sensor) RSSI() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.LastSample.RSSI
+}
+
+// Uptime returns the sensor's reported uptime, in seconds.
+func (s *Sensor) Uptime() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.LastSample.Uptime
+}
+
+// Refresh fetches and updates the cached Sensor state. It is
+// equivalent to RefreshContext with context.Background.
 func (s *Sensor) Refresh() error {
-	resp, err := http.Get(fmt.Sprint("http://", s.Addr, "/json?live=true"))
+	return s.RefreshContext(context.Background())
+}
+
+// RefreshContext is like Refresh, but honors ctx for cancellation and
+// per-request timeouts.
+func (s *Sensor) RefreshContext(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprint("http://", s.Addr, "/json?live=true"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -201,5 +358,28 @@ func (s *Sensor) Refresh() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.LastSample = status
+	if s.tempSmoother != nil {
+		s.tempSmoother.Add(actualTemp(s.TempPoly, float64(status.CurrentTempF)))
+	}
+	if s.aqiCombiner != nil {
+		s.aqiCombiner.Add(float64(status.PM25Aqi), float64(status.PM25AqiB))
+	}
+	if s.store != nil {
+		sample := store.Sample{
+			Time:     time.Now(),
+			SensorID: s.storeID,
+			Place:    s.storePlace,
+			Temp:     actualTemp(s.TempPoly, float64(status.CurrentTempF)),
+			Humidity: actualTemp(s.HumidityPoly, float64(status.CurrentHumidity)),
+			Pressure: actualTemp(s.PressurePoly, status.Pressure),
+			PM25A:    status.PM25Atm,
+			PM25B:    status.PM25AtmB,
+			AQIA:     float64(status.PM25Aqi),
+			AQIB:     float64(status.PM25AqiB),
+		}
+		if err := s.store.Append(ctx, sample); err != nil {
+			return err
+		}
+	}
 	return nil
 }
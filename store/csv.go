@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// csvHeader is the column order written to and expected from a CSV
+// store's header row.
+var csvHeader = []string{"time", "sensor_id", "place", "temp", "humidity", "pressure", "pm25_a", "pm25_b", "aqi_a", "aqi_b"}
+
+// csvStore is a Store backed by a single append-only CSV file. Query
+// re-reads the whole file and filters in memory, which is fine for
+// the modest sample volumes a handful of PurpleAirs produce.
+type csvStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// OpenCSV opens or creates a CSV store at path, writing a header row
+// if the file is new.
+func OpenCSV(path string) (Store, error) {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %q: %w", path, err)
+		}
+		w := csv.NewWriter(f)
+		if err := w.Write(csvHeader); err != nil {
+			f.Close()
+			return nil, err
+		}
+		w.Flush()
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat %q: %w", path, err)
+	}
+	return &csvStore{path: path}, nil
+}
+
+// Append implements Store.
+func (c *csvStore) Append(ctx context.Context, s Sample) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", c.path, err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err := w.Write(sampleToRow(s)); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Query implements Store.
+func (c *csvStore) Query(ctx context.Context, r Range) ([]Sample, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := os.Open(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %w", c.path, err)
+	}
+	defer f.Close()
+
+	rd := csv.NewReader(f)
+	rows, err := rd.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var out []Sample
+	for _, row := range rows[1:] {
+		s, err := rowToSample(row)
+		if err != nil {
+			return nil, err
+		}
+		if s.Time.Before(r.Start) || !s.Time.Before(r.End) {
+			continue
+		}
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, nil
+}
+
+// Close implements Store.
+func (c *csvStore) Close() error {
+	return nil
+}
+
+func sampleToRow(s Sample) []string {
+	return []string{
+		s.Time.Format(time.RFC3339Nano),
+		s.SensorID,
+		s.Place,
+		strconv.FormatFloat(s.Temp, 'g', -1, 64),
+		strconv.FormatFloat(s.Humidity, 'g', -1, 64),
+		strconv.FormatFloat(s.Pressure, 'g', -1, 64),
+		strconv.FormatFloat(s.PM25A, 'g', -1, 64),
+		strconv.FormatFloat(s.PM25B, 'g', -1, 64),
+		strconv.FormatFloat(s.AQIA, 'g', -1, 64),
+		strconv.FormatFloat(s.AQIB, 'g', -1, 64),
+	}
+}
+
+func rowToSample(row []string) (Sample, error) {
+	if len(row) != len(csvHeader) {
+		return Sample{}, fmt.Errorf("malformed row, want %d columns, got %d", len(csvHeader), len(row))
+	}
+	t, err := time.Parse(time.RFC3339Nano, row[0])
+	if err != nil {
+		return Sample{}, fmt.Errorf("malformed timestamp %q: %w", row[0], err)
+	}
+	vals := make([]float64, 7)
+	for i, v := range row[3:] {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Sample{}, fmt.Errorf("malformed value %q: %w", v, err)
+		}
+		vals[i] = f
+	}
+	return Sample{
+		Time:     t,
+		SensorID: row[1],
+		Place:    row[2],
+		Temp:     vals[0],
+		Humidity: vals[1],
+		Pressure: vals[2],
+		PM25A:    vals[3],
+		PM25B:    vals[4],
+		AQIA:     vals[5],
+		AQIB:     vals[6],
+	}, nil
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// OpenPostgres opens a Postgres-backed Store for the given connection
+// string (see github.com/lib/pq for the accepted forms).
+func OpenPostgres(dsn string) (Store, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres database: %w", err)
+	}
+	return newSQLStore(db, rebindPostgres)
+}
+
+// rebindPostgres rewrites ? placeholders into Postgres's $1, $2, ...
+// style.
+func rebindPostgres(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// schema is the samples table shared by the SQL-backed stores.
+// Placeholders use ? (rebound to $n for Postgres by bind).
+const schema = `
+CREATE TABLE IF NOT EXISTS samples (
+	time      TIMESTAMP NOT NULL,
+	sensor_id TEXT NOT NULL,
+	place     TEXT NOT NULL,
+	temp      DOUBLE PRECISION NOT NULL,
+	humidity  DOUBLE PRECISION NOT NULL,
+	pressure  DOUBLE PRECISION NOT NULL,
+	pm25_a    DOUBLE PRECISION NOT NULL,
+	pm25_b    DOUBLE PRECISION NOT NULL,
+	aqi_a     DOUBLE PRECISION NOT NULL,
+	aqi_b     DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX IF NOT EXISTS samples_time_idx ON samples (time);
+`
+
+// sqlStore implements Store over database/sql, shared by the SQLite
+// and Postgres backends. bind rewrites a query's ? placeholders into
+// the driver's native placeholder style.
+type sqlStore struct {
+	db   *sql.DB
+	bind func(query string) string
+}
+
+func newSQLStore(db *sql.DB, bind func(string) string) (*sqlStore, error) {
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+	return &sqlStore{db: db, bind: bind}, nil
+}
+
+// Append implements Store.
+func (s *sqlStore) Append(ctx context.Context, sm Sample) error {
+	q := s.bind(`INSERT INTO samples (time, sensor_id, place, temp, humidity, pressure, pm25_a, pm25_b, aqi_a, aqi_b)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	_, err := s.db.ExecContext(ctx, q, sm.Time, sm.SensorID, sm.Place, sm.Temp, sm.Humidity, sm.Pressure, sm.PM25A, sm.PM25B, sm.AQIA, sm.AQIB)
+	return err
+}
+
+// Query implements Store.
+func (s *sqlStore) Query(ctx context.Context, r Range) ([]Sample, error) {
+	q := s.bind(`SELECT time, sensor_id, place, temp, humidity, pressure, pm25_a, pm25_b, aqi_a, aqi_b
+		FROM samples WHERE time >= ? AND time < ? ORDER BY time`)
+	rows, err := s.db.QueryContext(ctx, q, r.Start, r.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Sample
+	for rows.Next() {
+		var sm Sample
+		if err := rows.Scan(&sm.Time, &sm.SensorID, &sm.Place, &sm.Temp, &sm.Humidity, &sm.Pressure, &sm.PM25A, &sm.PM25B, &sm.AQIA, &sm.AQIB); err != nil {
+			return nil, err
+		}
+		out = append(out, sm)
+	}
+	return out, rows.Err()
+}
+
+// Close implements Store.
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// noRebind leaves ? placeholders as-is, for drivers that accept them
+// natively (e.g. SQLite).
+func noRebind(query string) string {
+	return query
+}
@@ -0,0 +1,17 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// OpenSQLite opens or creates a SQLite-backed Store at path.
+func OpenSQLite(path string) (Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %q: %w", path, err)
+	}
+	return newSQLStore(db, noRebind)
+}
@@ -0,0 +1,64 @@
+// Package store persists normalized PurpleAir readings to a
+// time-series backend, so a Sensor's history can be queried after the
+// fact. CSV, SQLite and Postgres backends are provided.
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sample is a single normalized reading from a Sensor, suitable for
+// time-series storage independent of the PurpleAir wire format.
+type Sample struct {
+	Time     time.Time
+	SensorID string
+	Place    string
+	Temp     float64
+	Humidity float64
+	Pressure float64
+	PM25A    float64
+	PM25B    float64
+	AQIA     float64
+	AQIB     float64
+}
+
+// Range selects a half-open time interval [Start, End) for Query.
+type Range struct {
+	Start, End time.Time
+}
+
+// Store persists and retrieves Samples.
+type Store interface {
+	// Append records a single Sample.
+	Append(ctx context.Context, s Sample) error
+
+	// Query returns the Samples recorded within r, ordered by time.
+	Query(ctx context.Context, r Range) ([]Sample, error)
+
+	// Close releases any resources held by the Store.
+	Close() error
+}
+
+// Open opens a Store given a URL of the form file://path,
+// sqlite://path or postgres://dsn. file:// and sqlite:// paths name a
+// local file; postgres:// is passed through verbatim as a connection
+// string.
+func Open(rawURL string) (Store, error) {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return nil, fmt.Errorf("malformed store URL %q, want scheme://...", rawURL)
+	}
+	switch scheme {
+	case "file":
+		return OpenCSV(rest)
+	case "sqlite":
+		return OpenSQLite(rest)
+	case "postgres", "postgresql":
+		return OpenPostgres(rawURL)
+	default:
+		return nil, fmt.Errorf("unsupported store scheme %q", scheme)
+	}
+}